@@ -45,12 +45,26 @@ type Argument struct {
 	id string
 	// word rappresenta l'argomento nella linea di comando.
 	word string
+	// ShortWord è la forma breve dell'argomento, es. "-o". Vuota se non usata.
+	ShortWord string
+	// LongWord è la forma lunga dell'argomento, es. "--output". Vuota se non usata.
+	LongWord string
 	// Required indica se l'argomento deve essere specificato.
 	Required bool
 	// Value contiene il valore.
 	Value interface{}
 	// Help contiene la stringa che descrive l'argomento.
 	Help string
+	// EnvVar, se impostato, è il nome della variabile d'ambiente consultata quando l'argomento non è specificato in riga di comando.
+	EnvVar string
+	// Default è il valore testuale usato quando l'argomento non è specificato né in riga di comando né tramite EnvVar o ConfigLoader.
+	Default string
+	// CompleteFunc, se impostato, fornisce il completamento dinamico del valore dell'argomento (es. nomi di file, risorse remote).
+	CompleteFunc CompleteFunc
+	// CompletionHint configura il completamento dinamico usato dai generatori di completamento delle shell, vedi CompletionHint. Il valore zero non ha effetto.
+	CompletionHint CompletionHint
+	// resolved indica che il valore è già stato impostato tramite EnvVar, ConfigLoader o Default (preloadFallbacks o LoadInto), prima ancora che Parse esamini la riga di comando: in tal caso match non deve considerare mancante un argomento richiesto che non corrisponde al token corrente, ma lasciare che il ciclo di analisi prosegua con l'argomento successivo.
+	resolved bool
 }
 
 /*
@@ -70,6 +84,16 @@ type ArgumentStore interface {
 	StoreValue(str string, parent Argument) (err error)
 }
 
+/*
+ArgumentStorer ha la stessa forma di ArgumentStore: ogni ArgumentStore è già un ArgumentStorer, non serve alcun adattamento.
+
+È usata da BindStruct per riconoscere un campo di tipo struct (es. un tipo indirizzo IP o URL personalizzato) che sa memorizzare da sé il proprio valore, cosicché venga legato come un normale argomento invece di essere trattato come un gruppo di sotto-argomenti di un'opzione (il trattamento riservato di norma ai campi struct, vedi BindStruct).
+*/
+type ArgumentStorer interface {
+	// StoreValue memorizza il valore di un argomento e restituisce l'eventuale errore.
+	StoreValue(str string, parent Argument) (err error)
+}
+
 /*
 NewGenericArg crea un argomento generico.
 
@@ -88,6 +112,27 @@ func NewArg(argWord string, argID string, argRequired bool, argValue interface{}
 	return &Argument{id: argID, word: argWord, Required: argRequired, Value: argValue, Help: argHelp}
 }
 
+/*
+NewShortLongArg crea un argomento nominale con forma breve e forma lunga, es. "-o" e "--output".
+
+Almeno una delle due parole deve essere specificata. La parola restituita da Word è la forma lunga se presente, altrimenti quella breve, per compatibilità con il codice che usa solo word.
+*/
+func NewShortLongArg(shortWord string, longWord string, argID string, argRequired bool, argValue interface{}, argHelp string) *Argument {
+	if len(argID) == 0 {
+		return nil
+	}
+	shortWord = strings.TrimSpace(shortWord)
+	longWord = strings.TrimSpace(longWord)
+	if len(shortWord) == 0 && len(longWord) == 0 {
+		return nil
+	}
+	word := longWord
+	if len(word) == 0 {
+		word = shortWord
+	}
+	return &Argument{id: argID, word: word, ShortWord: shortWord, LongWord: longWord, Required: argRequired, Value: argValue, Help: argHelp}
+}
+
 // ID restituisce l'identificativo dell'argomento.
 // L'identificativo è usato al posto della parola per rappresentare gli argomenti generici nelle stringhe di help e di errore.
 func (arg *Argument) ID() string {
@@ -104,16 +149,38 @@ func (arg *Argument) IsGeneric() bool {
 	return (len(arg.word) == 0)
 }
 
+// words restituisce tutte le parole valide per l'argomento (word, ShortWord, LongWord), senza duplicati e senza stringhe vuote.
+func (arg *Argument) words() []string {
+	seen := make(map[string]bool, 3)
+	words := make([]string, 0, 3)
+	for _, w := range []string{arg.word, arg.ShortWord, arg.LongWord} {
+		if len(w) > 0 && !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
 // Describe restituisce una stringa che descrive la sintassi dell'argomento.
 // L'identificativo dell'argomento è usato al posto della parola per rappresentare gli argomenti generici.
+// Se sono impostate sia ShortWord che LongWord, la sintassi è "-o, --opt".
 func (arg *Argument) Describe(showOptional bool, showValue bool) string {
 	var str string
 	if arg.IsGeneric() {
 		str = ("<" + arg.id + ">")
 	} else {
-		str = arg.word
+		if len(arg.ShortWord) > 0 && len(arg.LongWord) > 0 {
+			str = arg.ShortWord + ", " + arg.LongWord
+		} else {
+			str = arg.word
+		}
 		if showValue {
-			str += "=value"
+			if cv, ok := arg.Value.(interface{ Choices() []string }); ok {
+				str += "={" + strings.Join(cv.Choices(), "|") + "}"
+			} else {
+				str += "=value"
+			}
 		}
 	}
 	if (arg.Required == false) && showOptional {
@@ -130,14 +197,16 @@ func (arg *Argument) match(s string) (result bool, err error) {
 		err = arg.storeValue(s)
 		return
 	}
-	// argomento con word e valore
-	if sv := strings.TrimPrefix(s, (arg.word + "=")); sv != s {
-		result = true
-		err = arg.storeValue(sv)
-		return
+	// argomento con word (o ShortWord/LongWord) e valore, es. word=value, --opt=value, -o=value
+	for _, w := range arg.words() {
+		if sv := strings.TrimPrefix(s, (w + "=")); sv != s {
+			result = true
+			err = arg.storeValue(sv)
+			return
+		}
 	}
-	// argomento non corrispondente
-	if arg.Required {
+	// argomento non corrispondente: se il valore è già stato risolto in anticipo (env/ConfigLoader/Default, vedi resolved) non è un errore, l'argomento è semplicemente saltato e il ciclo di analisi prova il token sull'argomento successivo
+	if arg.Required && !arg.resolved {
 		if arg.IsGeneric() {
 			err = NewExpectedArgError(arg.id)
 		} else {
@@ -245,6 +314,8 @@ func (arg *Argument) storeValue(str string) (err error) {
 			err = v(str, *arg)
 		case ArgumentStore:
 			err = v.StoreValue(str, *arg)
+		case ValueParser:
+			err = v.Parse(str)
 		case *float32:
 			var fval float64
 			fval, err = strconv.ParseFloat(str, 32)
@@ -286,10 +357,11 @@ func (al ArgumentList) HelpStrings(listIndent int) (line string, list string) {
 	for _, a := range al {
 		if a != nil {
 			items = append(items, a.Describe(true, true))
+			extra := envDefaultSuffix(a.EnvVar, a.Default)
 			if len(a.Help) > 0 {
-				list += fmt.Sprintf("\n%[1]s%[2]s\n  %[1]s%[3]s\n", indent, a.Describe(false, false), a.Help)
+				list += fmt.Sprintf("\n%[1]s%[2]s%[4]s\n  %[1]s%[3]s\n", indent, a.Describe(false, false), a.Help, extra)
 			} else {
-				list += fmt.Sprintf("\n%s%s\n", indent, a.Describe(false, false))
+				list += fmt.Sprintf("\n%s%s%s\n", indent, a.Describe(false, false), extra)
 			}
 		}
 	}
@@ -0,0 +1,207 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+BindStruct registra in cmd gli argomenti e le opzioni descritti dai tag "clap" dei campi esportati di v, che deve essere un puntatore a struct.
+
+Il tag ha la forma
+
+  clap:"parola,id[,required]"
+
+Il campo "help" (tag separato, es. `help:"..."`) fornisce il testo di aiuto. I campi privi del tag "clap" sono ignorati.
+
+Un campo bool diventa un'opzione (AppendNewOpt); gli altri tipi supportati diventano un argomento nominale (AppendNewArg):
+
+  string
+  int
+  int64
+  float64
+  time.Duration (tramite ValueParser, vedi durationValue)
+  []string (tramite NewSliceArg)
+
+Un campo di tipo struct (diverso da time.Duration) che implementa ArgumentStorer (cioè il cui puntatore ha un metodo StoreValue, vedi ArgumentStorer) diventa un argomento nominale come gli altri tipi supportati, utile per un tipo personalizzato (es. un indirizzo IP o un URL) che sa memorizzare da sé il proprio valore.
+
+Un campo di tipo struct che non implementa ArgumentStorer diventa a sua volta un'opzione, i cui campi innestati sono registrati come gli argomenti di quell'opzione (Option.Args), utile per raggruppare gli argomenti propri di una singola opzione, es.
+
+  type Opts struct {
+    Verbose bool `clap:"-v,verbose" help:"output esteso"`
+    Output  struct {
+      Path string `clap:"path,output-path,required" help:"percorso del file di output"`
+    } `clap:"-o,output" help:"scrive su file anziché su stdout"`
+  }
+
+L'ordine dei campi determina l'ordine di analisi degli argomenti (vedi l'introduzione del package); BindStruct restituisce un errore se un argomento richiesto segue un argomento opzionale nella stessa sequenza (quella del comando o quella di una singola opzione).
+*/
+func BindStruct(cmd *Command, v interface{}) error {
+	if cmd == nil {
+		return fmt.Errorf("clap: BindStruct: cmd is nil")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("clap: BindStruct: v must be a non-nil pointer to struct")
+	}
+	return bindFields(cmd, nil, rv.Elem())
+}
+
+// argHolder è l'interfaccia comune a Command e Option per l'aggiunta di argomenti, usata da bindFields per trattarli allo stesso modo.
+type argHolder interface {
+	AppendArg(arg *Argument)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// argumentStorerType è il reflect.Type dell'interfaccia ArgumentStorer, usato da bindFields per riconoscere un campo struct che sa memorizzare da sé il proprio valore.
+var argumentStorerType = reflect.TypeOf((*ArgumentStorer)(nil)).Elem()
+
+// bindFields registra i campi taggati di rv come argomenti di holder (cmd o opt, a seconda di chi ha richiamato la funzione), o come opzioni di cmd se holder è cmd.
+func bindFields(cmd *Command, opt *Option, rv reflect.Value) error {
+	holder, err := fieldHolder(cmd, opt)
+	if err != nil {
+		return err
+	}
+	rt := rv.Type()
+	sawOptional := false
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("clap")
+		if !ok || len(field.PkgPath) > 0 {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		word := strings.TrimSpace(parts[0])
+		id := field.Name
+		if len(parts) > 1 && len(strings.TrimSpace(parts[1])) > 0 {
+			id = strings.TrimSpace(parts[1])
+		}
+		required := len(parts) > 2 && strings.TrimSpace(parts[2]) == "required"
+		help := field.Tag.Get("help")
+		fv := rv.Field(i)
+
+		if !fv.CanAddr() {
+			return fmt.Errorf("clap: BindStruct: campo %q non indirizzabile", field.Name)
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+			// un campo struct che implementa ArgumentStorer (es. un tipo indirizzo IP o URL) sa memorizzare da sé il proprio valore: è legato come un normale argomento, non come gruppo di sotto-argomenti di un'opzione.
+			if fv.Addr().Type().Implements(argumentStorerType) {
+				if required && sawOptional {
+					return fmt.Errorf("clap: BindStruct: campo %q: argomento richiesto dopo un argomento opzionale", field.Name)
+				}
+				if !required {
+					sawOptional = true
+				}
+				arg := NewArg(word, id, required, fv.Addr().Interface(), help)
+				if arg == nil {
+					return fmt.Errorf("clap: BindStruct: campo %q: creazione dell'argomento fallita", field.Name)
+				}
+				holder.AppendArg(arg)
+				continue
+			}
+			if cmd == nil {
+				return fmt.Errorf("clap: BindStruct: campo %q: un'opzione non può contenere un'altra opzione", field.Name)
+			}
+			newOpt := cmd.AppendNewOpt(word, id, nil, help)
+			if newOpt == nil {
+				return fmt.Errorf("clap: BindStruct: campo %q: parola opzione mancante", field.Name)
+			}
+			if err := bindFields(nil, newOpt, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if fv.Kind() == reflect.Bool {
+			if cmd == nil {
+				return fmt.Errorf("clap: BindStruct: campo %q: un argomento di un'opzione non può essere bool", field.Name)
+			}
+			if cmd.AppendNewOpt(word, id, fv.Addr().Interface().(*bool), help) == nil {
+				return fmt.Errorf("clap: BindStruct: campo %q: parola opzione mancante", field.Name)
+			}
+			continue
+		}
+
+		if required && sawOptional {
+			return fmt.Errorf("clap: BindStruct: campo %q: argomento richiesto dopo un argomento opzionale", field.Name)
+		}
+		if !required {
+			sawOptional = true
+		}
+
+		var arg *Argument
+		switch fv.Kind() {
+		case reflect.String:
+			arg = NewArg(word, id, required, fv.Addr().Interface(), help)
+		case reflect.Int:
+			arg = NewArg(word, id, required, fv.Addr().Interface(), help)
+		case reflect.Int64:
+			if fv.Type() == durationType {
+				arg = NewArg(word, id, required, &durationValue{dest: fv.Addr().Interface().(*time.Duration)}, help)
+			} else {
+				arg = NewArg(word, id, required, fv.Addr().Interface(), help)
+			}
+		case reflect.Float64:
+			arg = NewArg(word, id, required, fv.Addr().Interface(), help)
+		case reflect.Slice:
+			if fv.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("clap: BindStruct: campo %q: tipo slice non supportato", field.Name)
+			}
+			arg = NewSliceArg(word, id, required, fv.Addr().Interface(), help)
+		default:
+			return fmt.Errorf("clap: BindStruct: campo %q: tipo %s non supportato", field.Name, fv.Type())
+		}
+		if arg == nil {
+			return fmt.Errorf("clap: BindStruct: campo %q: creazione dell'argomento fallita", field.Name)
+		}
+		holder.AppendArg(arg)
+	}
+	return nil
+}
+
+// fieldHolder restituisce l'argHolder su cui bindFields deve registrare gli argomenti: opt se impostato, altrimenti cmd.
+func fieldHolder(cmd *Command, opt *Option) (argHolder, error) {
+	if opt != nil {
+		return opt, nil
+	}
+	if cmd != nil {
+		return cmd, nil
+	}
+	return nil, fmt.Errorf("clap: BindStruct: né cmd né opt sono impostati")
+}
+
+/*
+durationValue è il ValueParser usato da BindStruct per i campi time.Duration, analizzati con time.ParseDuration (es. "1h30m", "500ms").
+*/
+type durationValue struct {
+	dest *time.Duration
+}
+
+// Parse analizza str con time.ParseDuration e lo memorizza in dest.
+func (d *durationValue) Parse(str string) error {
+	v, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d.dest = v
+	return nil
+}
+
+// String restituisce la rappresentazione testuale della durata corrente.
+func (d *durationValue) String() string {
+	if d.dest == nil {
+		return ""
+	}
+	return d.dest.String()
+}
+
+// Type restituisce "duration".
+func (d *durationValue) Type() string {
+	return "duration"
+}
@@ -31,6 +31,8 @@ Le opzioni sono sempre contraddistinte da una parola a cui può seguire un valor
 
 se il valore non è specificato, l'opzione acquisisce il valore default MatchValue impostato nella sua definizione.
 
+Per la sola forma lunga (LongWord) è riconosciuta anche la forma a token successivo "--opt value", dove value è un token separato con la stessa sintassi booleana di "=value".
+
 Un'opzione può avere anche una serie di argomenti che devono essere indicati dopo essa nella riga di comando.
 
 I valori degli argomenti sono convertiti nei tipi standard o attribuiti con StoreValue e ArgumentStore, vedi la descrizione di Argument, mentre il valore di un'opzione è bool, vedi la descrizione di Option.
@@ -199,7 +201,7 @@ Se la prima stringa in args non è un comando fra quelli contenuti in cmdMap, la
 La funzione si interrompe quando ha analizzato tutte le stringhe oppure se c'è un errore nell'attribuzione di un valore, se c'è un argomento sconosciuto o se un argomento obbligatorio non è stato specificato.
 La funzione restituisce l'oggetto Command che ha individuato insieme all'eventuale errore.
 
-Se args è vuoto o cmdMap non contiene il comando e cmdDefault è nil, la funzione restituisce nil come comando. Se cmdDefault è nil, restituisce anche un errore di comando sconosciuto.
+Se args è vuoto o cmdMap non contiene il comando e cmdDefault è nil, la funzione restituisce nil come comando. Se cmdDefault è nil, restituisce anche un errore *ErrUnknownArg, che propone fino a 3 comandi di cmdMap il cui nome è simile al token non riconosciuto.
 
 */
 func Parse(args []string, cmdMap CommandMap, cmdDefault *Command) (cmd *Command, err error) {
@@ -219,7 +221,7 @@ func Parse(args []string, cmdMap CommandMap, cmdDefault *Command) (cmd *Command,
 	} else {
 		// comando non trovato
 		if cmdDefault == nil {
-			return nil, NewUnknownArgError(args[count])
+			return nil, newUnknownCommandError(cmdMap, args[count])
 		}
 		// usa il comando default
 		cmd = cmdDefault
@@ -243,12 +245,29 @@ func Parse(args []string, cmdMap CommandMap, cmdDefault *Command) (cmd *Command,
 				}
 				// cerca fra le opzioni
 				oldcnt := count
+				// espande un eventuale bundle di opzioni brevi booleane, es. -xvf diventa -x -v -f
+				if bundle := cmd.Opts.expandShortBundle(args[count]); bundle != nil {
+					expanded := make([]string, 0, len(args)+len(bundle)-1)
+					expanded = append(expanded, args[:count]...)
+					expanded = append(expanded, bundle...)
+					expanded = append(expanded, args[count+1:]...)
+					args = expanded
+				}
 				curOpt = ole.next()
 				for curOpt != nil {
-					if result, err = curOpt.match(args[count]); err == nil {
+					matchedWord := args[count]
+					if result, err = curOpt.match(matchedWord); err == nil {
 						if result {
 							// opzione corrispondente
 							count++ // passa alla stringa successiva
+							// forma a token successivo delle opzioni lunghe, es. "--opt value" oltre a "--opt=value":
+							// si applica solo al riconoscimento della sola parola lunga, non a "parola=valore" né alle opzioni brevi
+							if len(curOpt.LongWord) > 0 && matchedWord == curOpt.LongWord && count < len(args) {
+								if b, valid := parseBoolToken(args[count]); valid {
+									curOpt.storeValue(b)
+									count++
+								}
+							}
 							// avvia l'enumeratore degli argomenti dell'opzione
 							ale = createArgListEnum(curOpt.Args)
 							ale.reset()
@@ -265,7 +284,7 @@ func Parse(args []string, cmdMap CommandMap, cmdDefault *Command) (cmd *Command,
 				} // for curOpt != nil
 				if oldcnt == count {
 					// nessuna corrispondenza
-					err = NewUnknownArgError(args[count])
+					err = NewUnknownArgError(cmd, args[count])
 					return
 				}
 			} else { // curArg != nil
@@ -286,14 +305,25 @@ func Parse(args []string, cmdMap CommandMap, cmdDefault *Command) (cmd *Command,
 			skipped := make([]string, 1)
 			for curArg != nil {
 				if curArg.Required {
-					// ci deve essere un argomento
-					skipped = append(skipped, curArg.Describe(true, false))
-					err = NewExpectedArgError(strings.Join(skipped, " "))
+					// prima di arrendersi, consulta env -> ConfigLoader -> Default
+					if v, ok := resolveFallback(cmd, curArg.word, curArg.EnvVar, curArg.Default); ok {
+						if serr := curArg.storeValue(v); serr != nil {
+							err = serr
+							return
+						}
+					} else {
+						// ci deve essere un argomento
+						skipped = append(skipped, curArg.Describe(true, false))
+						err = NewExpectedArgError(strings.Join(skipped, " "))
+					}
 				} else {
 					skipped = append(skipped, curArg.Describe(true, false))
 				}
 				curArg = ale.next()
 			}
+			if err == nil {
+				err = cmd.validateGroups()
+			}
 			return
 		}
 	} // ciclo di analisi argomenti
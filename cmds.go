@@ -5,6 +5,7 @@ package clap
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -37,6 +38,8 @@ type Command struct {
 	Args ArgumentList
 	// Opts contiene le opzioni del comando.
 	Opts OptionList
+	// Groups contiene i gruppi di opzioni mutuamente esclusive o da richiedere insieme, vedi AppendOptGroup.
+	Groups []*OptionGroup
 	// Data contiene i dati relativi al comando.
 	Data interface{}
 	// Prep contiene la funzione di preparazione del comando.
@@ -45,6 +48,14 @@ type Command struct {
 	Exec ExecuteCommand
 	//Help contiene la stringa che descrive il comando.
 	Help string
+	// ManSection è la sezione del manuale Unix in cui il comando va documentato, vedi CommandMap.GenerateMan. 1 se non impostato.
+	ManSection int
+	// SeeAlso contiene le parole di altri comandi correlati, riportate nella sezione SEE ALSO generata da CommandMap.GenerateMan e GenerateMarkdown.
+	SeeAlso []string
+	// prepared indica se Prepare è già stata eseguita, per evitare di richiamare Prep più volte (es. quando un chiamante prepara il comando in anticipo, con LoadInto o FullHelp, e Parse lo prepara di nuovo).
+	prepared bool
+	// internal indica che il comando è di uso interno (es. il comando "__complete" registrato da EnableCompletion) e va escluso da ShowHelp e dai generatori di help/completamento, pur restando riconoscibile da Parse.
+	internal bool
 }
 
 // PrepareCommand è il tipo funzione per la preparazione di un comando.
@@ -72,11 +83,46 @@ func (cmd *Command) Word() string {
 	return cmd.word
 }
 
-// Prepare è un metodo di comodo, chiama la funzione del campo Prep, se impostata, passando il comando stesso.
+/*
+Prepare è un metodo di comodo, chiama la funzione del campo Prep, se impostata, passando il comando stesso.
+
+Dopo la preparazione, precarica gli argomenti e le opzioni i cui campi EnvVar o Default sono impostati, seguendo l'ordine di precedenza env -> ConfigLoader -> Default. I valori letti dalla riga di comando sovrascrivono sempre questo precaricamento, perché Parse viene eseguito in seguito.
+
+Prepare è idempotente: le chiamate successive alla prima non hanno effetto. Questo permette a un chiamante di preparare il comando in anticipo (es. LoadInto o FullHelp) senza che Parse, che prepara sempre il comando individuato, richiami Prep una seconda volta duplicando gli Args/Opts che una funzione Prep dinamica registra ad ogni chiamata.
+*/
 func (cmd *Command) Prepare() {
+	if cmd.prepared {
+		return
+	}
+	cmd.prepared = true
 	if cmd.Prep != nil {
 		cmd.Prep(cmd)
 	}
+	cmd.preloadFallbacks()
+}
+
+// preloadFallbacks precarica Args e Opts del comando con i valori di EnvVar/ConfigLoader/Default, quando impostati.
+func (cmd *Command) preloadFallbacks() {
+	for _, arg := range cmd.Args {
+		if arg == nil || (len(arg.EnvVar) == 0 && len(arg.Default) == 0) {
+			continue
+		}
+		if v, ok := resolveFallback(cmd, arg.word, arg.EnvVar, arg.Default); ok {
+			if err := arg.storeValue(v); err == nil {
+				arg.resolved = true
+			}
+		}
+	}
+	for _, opt := range cmd.Opts {
+		if opt == nil || (len(opt.EnvVar) == 0 && len(opt.Default) == 0) {
+			continue
+		}
+		if v, ok := resolveFallback(cmd, opt.word, opt.EnvVar, opt.Default); ok {
+			if b, err := strconv.ParseBool(strings.ToLower(v)); err == nil {
+				opt.storeValue(b)
+			}
+		}
+	}
 }
 
 // Execute è un metodo di comodo, chiama la funzione del campo Exec, se impostata, passando i dati (campo Data) del comando.
@@ -120,6 +166,21 @@ func (cmd *Command) AppendNewOpt(optWord string, optID string, optValue *bool, o
 	return
 }
 
+/*
+resetOptions azzera lo stato delle opzioni del comando (wasSet e il valore puntato da Value), in modo che un chiamante che richiama Parse più volte sullo stesso Command (es. REPL.execute) non veda sopravvivere lo stato di un'esecuzione precedente, sia nel valore riportato all'utente sia nella validazione di Groups.
+*/
+func (cmd *Command) resetOptions() {
+	for _, opt := range cmd.Opts {
+		if opt == nil {
+			continue
+		}
+		opt.wasSet = false
+		if opt.Value != nil {
+			*opt.Value = false
+		}
+	}
+}
+
 // ShortHelp restituisce l'help breve del comando, ovvero word e help su una stessa riga.
 func (cmd *Command) ShortHelp() string {
 	return fmt.Sprintf("%s		%s", cmd.word, cmd.Help)
@@ -150,8 +211,31 @@ func (cmd *Command) FullHelp() string {
 		hlp += " "
 		hlp += line
 	}
-	// opzioni
-	line, optlist = cmd.Opts.HelpStrings(0)
+	// opzioni: nel sinottico, le opzioni raggruppate sono rese con la sintassi del loro OptionGroup
+	_, optlist = cmd.Opts.HelpStrings(0)
+	grouped := make(map[*Option]bool)
+	synopsis := make([]string, 0, len(cmd.Groups)+1)
+	for _, g := range cmd.Groups {
+		if g == nil {
+			continue
+		}
+		synopsis = append(synopsis, g.Synopsis())
+		for _, o := range g.Opts {
+			if o != nil {
+				grouped[o] = true
+			}
+		}
+	}
+	ungrouped := make(OptionList, 0, len(cmd.Opts))
+	for _, o := range cmd.Opts {
+		if o != nil && !grouped[o] {
+			ungrouped = append(ungrouped, o)
+		}
+	}
+	if ul, _ := ungrouped.HelpStrings(0); len(ul) > 0 {
+		synopsis = append(synopsis, ul)
+	}
+	line = strings.Join(synopsis, " ")
 	if len(line) > 0 {
 		hlp += " "
 		hlp += line
@@ -203,22 +287,31 @@ func (cm CommandMap) Remove(cmd *Command) {
 	}
 }
 
-// ShowHelp elenca i comandi nella console mostrando l'help accanto a ciascuno di essi.
+// ShowHelp elenca i comandi nella console mostrando l'help accanto a ciascuno di essi, escludendo i comandi interni (vedi Command.internal).
 func (cm CommandMap) ShowHelp() {
-	// ordina le chiavi
-	keys := make(sort.StringSlice, len(cm))
-	i := 0
-	for k := range cm {
-		keys[i] = k
-		i++
-	}
-	sort.Sort(keys)
-	// mostra l'help breve per ogni comando
-	var cmd *Command
-	var ok bool
-	for i = 0; i < len(keys); i++ {
-		if cmd, ok = cm[keys[i]]; ok {
-			cmd.ShowShortHelp()
+	for _, k := range cm.visibleKeys() {
+		cm[k].ShowShortHelp()
+	}
+}
+
+// visibleKeys restituisce le chiavi di cm ordinate, escludendo i comandi interni (es. il comando nascosto registrato da EnableCompletion). Usata da ShowHelp e dai generatori di help/completamento.
+func (cm CommandMap) visibleKeys() []string {
+	keys := make([]string, 0, len(cm))
+	for k, cmd := range cm {
+		if cmd != nil && cmd.internal {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resetOptions azzera lo stato delle opzioni di tutti i comandi della mappa, vedi Command.resetOptions.
+func (cm CommandMap) resetOptions() {
+	for _, cmd := range cm {
+		if cmd != nil {
+			cmd.resetOptions()
 		}
 	}
 }
@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"rmite/clap/completion"
+)
+
+/*
+CompleteFunc è il tipo funzione per il completamento dinamico del valore di un argomento (es. nomi di file, risorse remote).
+
+Riceve il prefisso già digitato e restituisce le proposte di completamento. È usata dai generatori di completamento per le shell, vedi Command.GenCompletion.
+*/
+type CompleteFunc func(prefix string) []string
+
+// optionWords restituisce tutte le parole (word, ShortWord, LongWord) delle opzioni del comando, ordinate.
+func (cmd *Command) optionWords() []string {
+	seen := make(map[string]bool)
+	words := make([]string, 0, len(cmd.Opts))
+	for _, o := range cmd.Opts {
+		if o == nil {
+			continue
+		}
+		for _, w := range o.words() {
+			if !seen[w] {
+				seen[w] = true
+				words = append(words, w)
+			}
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// argChoices restituisce, per ogni argomento del comando che espone Choices() (vedi NewChoiceArg), la sua parola e l'elenco delle scelte.
+func (cmd *Command) argChoices() map[string][]string {
+	choices := make(map[string][]string)
+	for _, a := range cmd.Args {
+		if a == nil || a.IsGeneric() {
+			continue
+		}
+		if cv, ok := a.Value.(interface{ Choices() []string }); ok {
+			choices[a.word] = cv.Choices()
+		}
+	}
+	return choices
+}
+
+/*
+GenCompletion genera su w lo script di completamento per la shell specificata ("bash", "zsh" o "fish"), delegando al package completion (lo stesso usato da CommandMap.GenerateBashCompletion e affini, vedi shellcompletion.go).
+
+Lo script completa le parole delle opzioni del comando (inclusi ShortWord/LongWord). Solo lo script bash riconosce la sintassi "parola=" per proporre il valore: "true|false|t|f|1|0" dopo "opt=" così come richiesto da Option.match, le scelte ammesse per gli argomenti creati con NewChoiceArg. Gli script zsh e fish completano solo la parola del comando e quelle delle opzioni, senza proporre valori.
+
+Il nome del programma è ricavato da os.Args[0], vedi progName.
+*/
+func (cmd *Command) GenCompletion(shell string, w io.Writer) error {
+	spec := []completion.CommandSpec{{Word: cmd.word, Opts: cmd.optionWords(), Choices: cmd.argChoices(), Args: cmd.argHints()}}
+	prog := progName()
+	var script string
+	switch shell {
+	case "bash":
+		script = completion.RenderBash(prog, spec)
+	case "zsh":
+		script = completion.RenderZsh(prog, spec)
+	case "fish":
+		script = completion.RenderFish(prog, spec)
+	default:
+		return fmt.Errorf("unsupported shell for completion: %s", shell)
+	}
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+/*
+GenCompletion genera su w lo script di completamento per la shell specificata, coprendo tutti i comandi della mappa (esclusi quelli interni, vedi Command.internal), delegando a CommandMap.GenerateBashCompletion/GenerateZshCompletion/GenerateFishCompletion.
+
+Non conosce un comando default (vedi l'introduzione del package): per includerlo nel completamento usa direttamente uno di quei metodi.
+*/
+func (cm CommandMap) GenCompletion(shell string, w io.Writer) error {
+	prog := progName()
+	switch shell {
+	case "bash":
+		return cm.GenerateBashCompletion(w, prog, nil)
+	case "zsh":
+		return cm.GenerateZshCompletion(w, prog, nil)
+	case "fish":
+		return cm.GenerateFishCompletion(w, prog, nil)
+	default:
+		return fmt.Errorf("unsupported shell for completion: %s", shell)
+	}
+}
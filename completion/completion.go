@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+/*
+Package completion fornisce i template testuali usati dal package clap per generare gli script di completamento delle shell supportate (bash, zsh, fish, PowerShell).
+
+Il package non dipende dai tipi di clap: riceve solo le informazioni già estratte (parole dei comandi, delle opzioni, le eventuali scelte ammesse e gli hint di completamento dinamico degli argomenti) tramite CommandSpec, in modo da poter essere testato e riusato senza un riferimento al package che lo richiama.
+*/
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommandSpec descrive un comando ai fini della generazione del completamento.
+type CommandSpec struct {
+	// Word è la parola che individua il comando in riga di comando.
+	Word string
+	// Opts contiene le parole delle opzioni del comando, incluse le eventuali forme ShortWord/LongWord.
+	Opts []string
+	// Choices associa la parola di un argomento alle scelte ammesse, per gli argomenti creati con clap.NewChoiceArg.
+	Choices map[string][]string
+	// Args contiene gli argomenti nominali con un completamento dinamico (file, directory o invocazione di "__complete"), vedi ArgSpec e clap.CompletionHint.
+	Args []ArgSpec
+}
+
+// ArgHintKind seleziona il tipo di completamento dinamico proposto per un argomento nello script generato, vedi ArgSpec.
+type ArgHintKind int
+
+const (
+	// NoHint è il valore zero, nessun completamento dinamico per l'argomento.
+	NoHint ArgHintKind = iota
+	// HintFile propone il completamento dei nomi di file (in bash, "compgen -f").
+	HintFile
+	// HintDir propone il completamento dei nomi di directory (in bash, "compgen -d").
+	HintDir
+	// HintDynamic propone le voci calcolate a runtime invocando il comando nascosto "__complete" registrato da clap.CommandMap.EnableCompletion, usato per CompletionHint.Values, CompletionHint.Func e Argument.CompleteFunc.
+	HintDynamic
+)
+
+// ArgSpec descrive un argomento nominale che richiede un completamento dinamico.
+type ArgSpec struct {
+	// Word è la parola che precede il valore dell'argomento (es. "-enc").
+	Word string
+	// Hint seleziona il tipo di completamento dinamico.
+	Hint ArgHintKind
+}
+
+func sanitize(word string) string {
+	r := strings.NewReplacer("-", "_", "=", "_", " ", "_", ".", "_")
+	return r.Replace(word)
+}
+
+func quoteAll(items []string) []string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = "'" + s + "'"
+	}
+	return quoted
+}
+
+func commandWords(cmds []CommandSpec) []string {
+	words := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		words = append(words, c.Word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// dynamicCaseFmt è il caso "parola=*" per un argomento con hint dinamico (vedi HintDynamic): propone le voci calcolate invocando il comando nascosto "__complete" con il prefisso già digitato.
+const dynamicCaseFmt = `        %s=*)
+          COMPREPLY=( $(compgen -W "$(%s __complete %s %s "${cur#*=}")" -- "${cur#*=}") )
+          return 0
+          ;;
+`
+
+/*
+RenderBash genera uno script di completamento bash per progName.
+
+Lo script completa i comandi in cmds e, per ciascuno, le sue opzioni; riconosce la sintassi "parola=" e propone "true false t f 1 0" per le opzioni booleane, le scelte ammesse per gli argomenti enumerati, i nomi di file o directory per gli argomenti con CompletionHint CompleteFile/CompleteDir, oppure invoca "__complete" per gli argomenti con un hint dinamico (Values o Func), vedi ArgSpec.
+*/
+func RenderBash(progName string, cmds []CommandSpec) string {
+	var b strings.Builder
+	fname := "_clap_" + sanitize(progName)
+	fmt.Fprintf(&b, "_%s() {\n", fname)
+	b.WriteString("  local cur prev cmd\n")
+	b.WriteString("  cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("  cmd=\"${COMP_WORDS[1]}\"\n")
+	fmt.Fprintf(&b, "  if [ \"$COMP_CWORD\" -eq 1 ]; then\n    COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n    return 0\n  fi\n", strings.Join(commandWords(cmds), " "))
+	b.WriteString("  case \"$cmd\" in\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    %s)\n", c.Word)
+		b.WriteString("      case \"$cur\" in\n")
+		for word, choices := range c.Choices {
+			fmt.Fprintf(&b, "        %s=*)\n          COMPREPLY=( $(compgen -W \"%s\" -- \"${cur#*=}\") )\n          return 0\n          ;;\n", word, strings.Join(choices, " "))
+		}
+		for _, a := range c.Args {
+			switch a.Hint {
+			case HintFile:
+				fmt.Fprintf(&b, "        %s=*)\n          COMPREPLY=( $(compgen -f -- \"${cur#*=}\") )\n          return 0\n          ;;\n", a.Word)
+			case HintDir:
+				fmt.Fprintf(&b, "        %s=*)\n          COMPREPLY=( $(compgen -d -- \"${cur#*=}\") )\n          return 0\n          ;;\n", a.Word)
+			case HintDynamic:
+				fmt.Fprintf(&b, dynamicCaseFmt, a.Word, progName, c.Word, a.Word)
+			}
+		}
+		for _, o := range c.Opts {
+			fmt.Fprintf(&b, "        %s=*)\n          COMPREPLY=( $(compgen -W \"true false t f 1 0\" -- \"${cur#*=}\") )\n          return 0\n          ;;\n", o)
+		}
+		b.WriteString("      esac\n")
+		fmt.Fprintf(&b, "      COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(c.Opts, " "))
+		b.WriteString("      return 0\n      ;;\n")
+	}
+	b.WriteString("  esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F _%s %s\n", fname, progName)
+	return b.String()
+}
+
+// RenderZsh genera uno script di completamento zsh per progName.
+func RenderZsh(progName string, cmds []CommandSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", progName)
+	fmt.Fprintf(&b, "_%s() {\n", sanitize(progName))
+	b.WriteString("  local -a cmds\n")
+	fmt.Fprintf(&b, "  cmds=(%s)\n", strings.Join(quoteAll(commandWords(cmds)), " "))
+	b.WriteString("  if (( CURRENT == 2 )); then\n    _describe 'command' cmds\n    return\n  fi\n")
+	b.WriteString("  local cmd=\"${words[2]}\"\n  local -a opts\n  case \"$cmd\" in\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    %s) opts=(%s) ;;\n", c.Word, strings.Join(quoteAll(c.Opts), " "))
+	}
+	b.WriteString("  esac\n  _describe 'option' opts\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RenderFish genera uno script di completamento fish per progName.
+func RenderFish(progName string, cmds []CommandSpec) string {
+	var b strings.Builder
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c %s -n '__fish_use_subcommand' -a %s\n", progName, c.Word)
+		for _, o := range c.Opts {
+			fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from %s' -a %s\n", progName, c.Word, o)
+		}
+	}
+	return b.String()
+}
+
+// RenderPowerShell genera uno script di completamento per PowerShell (Register-ArgumentCompleter) per progName.
+func RenderPowerShell(progName string, cmds []CommandSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", progName)
+	b.WriteString("  param($wordToComplete, $commandAst, $cursorPosition)\n")
+	b.WriteString("  $tokens = $commandAst.CommandElements | ForEach-Object { $_.Extent.Text }\n")
+	fmt.Fprintf(&b, "  $commands = @(%s)\n", strings.Join(quoteAll(commandWords(cmds)), ", "))
+	b.WriteString("  if ($tokens.Count -le 2) {\n    $commands | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object { $_ }\n    return\n  }\n")
+	b.WriteString("  $cmd = $tokens[1]\n  switch ($cmd) {\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    '%s' { @(%s) | Where-Object { $_ -like \"$wordToComplete*\" } }\n", c.Word, strings.Join(quoteAll(c.Opts), ", "))
+	}
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
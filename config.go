@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"os"
+)
+
+/*
+ConfigLoader è l'interfaccia per una sorgente di valori di default esterna alla riga di comando, tipicamente un file di configurazione INI/TOML/JSON.
+
+Load restituisce il valore configurato per la parola word del comando cmd, e true se presente.
+*/
+type ConfigLoader interface {
+	Load(cmd *Command, word string) (value string, ok bool)
+}
+
+/*
+ActiveConfigLoader è il ConfigLoader usato da Command.Prepare e da Parse per risolvere i valori non specificati in riga di comando.
+
+È nil di default, nel qual caso viene consultata solo la variabile d'ambiente (EnvVar) e il valore di Default.
+*/
+var ActiveConfigLoader ConfigLoader
+
+/*
+resolveFallback cerca un valore per la parola word del comando cmd seguendo l'ordine di precedenza env -> file di configurazione -> Default.
+
+Restituisce ok false se nessuna delle sorgenti fornisce un valore.
+*/
+func resolveFallback(cmd *Command, word string, envVar string, def string) (value string, ok bool) {
+	if len(envVar) > 0 {
+		if v, present := os.LookupEnv(envVar); present {
+			return v, true
+		}
+	}
+	if ActiveConfigLoader != nil {
+		if v, present := ActiveConfigLoader.Load(cmd, word); present {
+			return v, true
+		}
+	}
+	if len(def) > 0 {
+		return def, true
+	}
+	return "", false
+}
+
+// envDefaultSuffix restituisce la stringa "(env: FOO, default: "bar")" da aggiungere all'help, oppure una stringa vuota se né envVar né def sono impostati.
+func envDefaultSuffix(envVar string, def string) string {
+	if len(envVar) == 0 && len(def) == 0 {
+		return ""
+	}
+	switch {
+	case len(envVar) > 0 && len(def) > 0:
+		return fmt.Sprintf(" (env: %s, default: %q)", envVar, def)
+	case len(envVar) > 0:
+		return fmt.Sprintf(" (env: %s)", envVar)
+	default:
+		return fmt.Sprintf(" (default: %q)", def)
+	}
+}
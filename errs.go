@@ -2,35 +2,307 @@
 
 package clap
 
-// argError rappresenta un errore relativo a un argomento.
-type argError struct {
-	argName string
-	str     string
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorKind classifica la natura di un ParseError.
+type ErrorKind int
+
+const (
+	// KindUnknown indica un argomento o un'opzione non riconosciuti.
+	KindUnknown ErrorKind = iota
+	// KindExpected indica un argomento obbligatorio mancante.
+	KindExpected
+	// KindTooMuch indica un token oltre la fine della sequenza attesa.
+	KindTooMuch
+	// KindInvalidValue indica un valore che non può essere convertito nel tipo atteso.
+	KindInvalidValue
+	// KindAmbiguous indica un token che corrisponde a più di un argomento o opzione.
+	KindAmbiguous
+)
+
+// String restituisce la descrizione testuale del genere di errore.
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUnknown:
+		return "unknown"
+	case KindExpected:
+		return "expected"
+	case KindTooMuch:
+		return "too much arguments"
+	case KindInvalidValue:
+		return "invalid value"
+	case KindAmbiguous:
+		return "ambiguous"
+	default:
+		return "error"
+	}
 }
 
-// NewParseArgError crea un errore generico di parsing dell'argomento. Il parametro text descrive l'errore.
+/*
+ParseError rappresenta un errore di analisi della riga di comando.
+
+Kind ne classifica il genere, Name contiene il nome dell'argomento o dell'opzione coinvolti (può essere vuoto), Command è il comando durante la cui analisi si è verificato l'errore (può essere nil, ad esempio se il comando stesso non è stato riconosciuto).
+Suggestion, se non vuoto, propone la parola più simile a Name fra gli argomenti e le opzioni di Command.
+
+Is confronta solo Kind, così errors.Is può distinguere i generi di errore senza analizzare il messaggio, ad esempio errors.Is(err, clap.ErrUnknownArgument).
+*/
+type ParseError struct {
+	Kind       ErrorKind
+	Name       string
+	Command    *Command
+	Suggestion string
+}
+
+// Error implementa l'interfaccia error.
+func (e *ParseError) Error() string {
+	switch e.Kind {
+	case KindUnknown:
+		if len(e.Suggestion) > 0 {
+			return fmt.Sprintf("unknown argument '%s': did you mean '%s'?", e.Name, e.Suggestion)
+		}
+		if len(e.Name) > 0 {
+			return "unknown: " + e.Name
+		}
+		return "unknown"
+	default:
+		if len(e.Name) > 0 {
+			return e.Kind.String() + ": " + e.Name
+		}
+		return e.Kind.String()
+	}
+}
+
+// Is permette a errors.Is di riconoscere un ParseError confrontando solo Kind, ignorando Name, Command e Suggestion.
+func (e *ParseError) Is(target error) bool {
+	t, ok := target.(*ParseError)
+	if !ok {
+		return false
+	}
+	return e.Kind == t.Kind
+}
+
+// Sentinelle da usare con errors.Is, es. errors.Is(err, clap.ErrUnknownArgument).
+var (
+	ErrUnknownArgument  = &ParseError{Kind: KindUnknown}
+	ErrExpectedArgument = &ParseError{Kind: KindExpected}
+	ErrTooMuchArguments = &ParseError{Kind: KindTooMuch}
+	ErrInvalidValue     = &ParseError{Kind: KindInvalidValue}
+	ErrAmbiguous        = &ParseError{Kind: KindAmbiguous}
+)
+
+// NewParseArgError crea un errore generico di valore non valido. Il parametro text descrive l'errore: Name è "argument (text)".
 func NewParseArgError(argument string, text string) error {
-	return &argError{argName: argument, str: text}
+	return &ParseError{Kind: KindInvalidValue, Name: fmt.Sprintf("%s (%s)", argument, text)}
 }
 
 // NewExpectedArgError crea un errore per un argomento necessario.
 func NewExpectedArgError(argument string) error {
-	return &argError{argName: argument, str: "expected"}
+	return &ParseError{Kind: KindExpected, Name: argument}
 }
 
-// NewUnknownArgError crea un errore per un argomento sconosciuto.
-func NewUnknownArgError(argument string) error {
-	return &argError{argName: argument, str: "unknown"}
+/*
+NewUnknownArgError crea un errore per un argomento o un'opzione sconosciuti.
+
+Se cmd non è nil, cerca fra le parole dei suoi Args e Opts quella più simile ad argument secondo la distanza di Levenshtein e la propone in Error() come "did you mean '...'?". Vedi suggestWord per i dettagli della soglia e dell'ordinamento.
+*/
+func NewUnknownArgError(cmd *Command, argument string) error {
+	return &ParseError{Kind: KindUnknown, Name: argument, Command: cmd, Suggestion: suggestWord(cmd, argument)}
 }
 
 // NewTooMuchArgError crea un errore per un argomento oltre la fine della sequenza.
 func NewTooMuchArgError(argument string) error {
-	return &argError{argName: argument, str: "too much arguments"}
+	return &ParseError{Kind: KindTooMuch, Name: argument}
+}
+
+/*
+suggestWord cerca, fra le parole degli Args e degli Opts di cmd, quella più simile a token secondo la distanza di Levenshtein.
+
+Una parola è candidata se la sua distanza da token non supera max(2, len(token)/4). A parità di distanza vince la parola più corta, poi quella lessicograficamente minore. Restituisce una stringa vuota se cmd è nil o se nessuna parola supera la soglia.
+*/
+func suggestWord(cmd *Command, token string) string {
+	if cmd == nil {
+		return ""
+	}
+	candidates := make([]string, 0, len(cmd.Args)+len(cmd.Opts))
+	for _, a := range cmd.Args {
+		if a != nil {
+			candidates = append(candidates, a.words()...)
+		}
+	}
+	for _, o := range cmd.Opts {
+		if o != nil {
+			candidates = append(candidates, o.words()...)
+		}
+	}
+	threshold := len([]rune(token)) / 4
+	if threshold < 2 {
+		threshold = 2
+	}
+	best := ""
+	bestDist := -1
+	for _, c := range candidates {
+		d := levenshtein(token, c)
+		if d > threshold {
+			continue
+		}
+		if bestDist == -1 || d < bestDist ||
+			(d == bestDist && (len(c) < len(best) || (len(c) == len(best) && c < best))) {
+			best = c
+			bestDist = d
+		}
+	}
+	return best
 }
 
-func (e *argError) Error() string {
-	if len(e.argName) > 0 {
-		return (e.str + ": " + e.argName)
+/*
+SuggestionThreshold, se maggiore di zero, sostituisce la soglia di default usata da ErrUnknownArg per proporre i comandi più simili a un token sconosciuto.
+
+La soglia di default è max(2, len(token)/3).
+*/
+var SuggestionThreshold int
+
+/*
+ErrUnknownArg rappresenta il primo token di una linea di comando quando non corrisponde a nessun comando di una CommandMap e non è stato impostato un comando default per Parse.
+
+Suggestions contiene fino a 3 comandi di quella mappa il cui nome è sufficientemente simile a Token secondo la distanza di Damerau-Levenshtein, ordinati per distanza crescente e, a parità di distanza, in ordine lessicografico. È vuoto se nessun comando supera la soglia.
+*/
+type ErrUnknownArg struct {
+	Token       string
+	Suggestions []string
+}
+
+// Error implementa l'interfaccia error.
+func (e *ErrUnknownArg) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown command '%s'", e.Token)
+	}
+	quoted := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		quoted[i] = "'" + s + "'"
+	}
+	return fmt.Sprintf("unknown command '%s': did you mean %s?", e.Token, strings.Join(quoted, ", "))
+}
+
+// newUnknownCommandError crea un ErrUnknownArg per token, proponendo i comandi di cmdMap più simili secondo suggestCommands.
+func newUnknownCommandError(cmdMap CommandMap, token string) error {
+	return &ErrUnknownArg{Token: token, Suggestions: suggestCommands(cmdMap, token)}
+}
+
+// suggestCommands restituisce fino a 3 parole di cmdMap la cui distanza di Damerau-Levenshtein da token non supera SuggestionThreshold (o la soglia di default), ordinate per distanza e poi lessicograficamente.
+func suggestCommands(cmdMap CommandMap, token string) []string {
+	threshold := SuggestionThreshold
+	if threshold <= 0 {
+		threshold = len([]rune(token)) / 3
+		if threshold < 2 {
+			threshold = 2
+		}
+	}
+	type candidate struct {
+		word string
+		dist int
+	}
+	candidates := make([]candidate, 0, len(cmdMap))
+	for word := range cmdMap {
+		if d := damerauLevenshtein(token, word); d <= threshold {
+			candidates = append(candidates, candidate{word, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].word < candidates[j].word
+	})
+	if len(candidates) > 3 {
+		candidates = candidates[:3]
+	}
+	words := make([]string, len(candidates))
+	for i, c := range candidates {
+		words[i] = c.word
+	}
+	return words
+}
+
+/*
+damerauLevenshtein calcola la distanza di Damerau-Levenshtein fra a e b, cioè la distanza di Levenshtein estesa al riconoscimento della trasposizione di due caratteri adiacenti (es. "comnad" e "comand" distano 1 anziché 2).
+*/
+func damerauLevenshtein(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	la, lb := len(ra), len(rb)
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := d[i-1][j] + 1
+			ins := d[i][j-1] + 1
+			sub := d[i-1][j-1] + cost
+			m := del
+			if ins < m {
+				m = ins
+			}
+			if sub < m {
+				m = sub
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if t := d[i-2][j-2] + 1; t < m {
+					m = t
+				}
+			}
+			d[i][j] = m
+		}
+	}
+	return d[la][lb]
+}
+
+/*
+levenshtein calcola la distanza di Levenshtein fra a e b con la classica programmazione dinamica a due righe, O(n·m) in tempo e O(min(n,m)) in spazio.
+*/
+func levenshtein(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	prev := make([]int, len(ra)+1)
+	curr := make([]int, len(ra)+1)
+	for i := range prev {
+		prev[i] = i
+	}
+	for j := 1; j <= len(rb); j++ {
+		curr[0] = j
+		for i := 1; i <= len(ra); i++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := prev[i] + 1
+			ins := curr[i-1] + 1
+			sub := prev[i-1] + cost
+			m := del
+			if ins < m {
+				m = ins
+			}
+			if sub < m {
+				m = sub
+			}
+			curr[i] = m
+		}
+		prev, curr = curr, prev
 	}
-	return e.str
+	return prev[len(ra)]
 }
@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+OptionGroup rappresenta un vincolo fra un insieme di opzioni dello stesso comando.
+
+Exclusive richiede che al più una delle opzioni del gruppo sia stata specificata in riga di comando.
+RequireOne richiede che almeno una lo sia stata.
+RequireAll richiede che lo siano tutte.
+
+Il vincolo è verificato da Parse dopo aver esaurito la riga di comando, vedi validate.
+*/
+type OptionGroup struct {
+	Name       string
+	Exclusive  bool
+	RequireAll bool
+	RequireOne bool
+	Opts       []*Option
+}
+
+// NewOptionGroup crea un gruppo di opzioni con il nome e i vincoli specificati.
+func NewOptionGroup(name string, exclusive bool, requireAll bool, requireOne bool, opts ...*Option) *OptionGroup {
+	return &OptionGroup{Name: name, Exclusive: exclusive, RequireAll: requireAll, RequireOne: requireOne, Opts: opts}
+}
+
+/*
+Synopsis restituisce la sintassi sintetica del gruppo per l'help.
+
+I gruppi esclusivi sono resi come "{-a | -b | -c}", quelli con RequireAll come "(-a -b)", gli altri (RequireOne) come "(-a | -b)".
+*/
+func (g *OptionGroup) Synopsis() string {
+	words := make([]string, 0, len(g.Opts))
+	for _, o := range g.Opts {
+		if o != nil {
+			words = append(words, o.word)
+		}
+	}
+	if g.RequireAll {
+		return "(" + strings.Join(words, " ") + ")"
+	}
+	if g.Exclusive {
+		return "{" + strings.Join(words, " | ") + "}"
+	}
+	return "(" + strings.Join(words, " | ") + ")"
+}
+
+// GroupViolation classifica quale vincolo di un OptionGroup non è stato rispettato, usato da GroupError.Error per riportare il problema reale invece di dedurlo dai campi del gruppo.
+type GroupViolation int
+
+const (
+	// ViolationExclusive indica che più di un'opzione del gruppo Exclusive è stata specificata.
+	ViolationExclusive GroupViolation = iota
+	// ViolationRequireOne indica che nessuna opzione del gruppo RequireOne è stata specificata.
+	ViolationRequireOne
+	// ViolationRequireAll indica che almeno un'opzione del gruppo RequireAll non è stata specificata.
+	ViolationRequireAll
+)
+
+// validate controlla i vincoli del gruppo dopo l'analisi della riga di comando e restituisce il GroupError relativo alla prima violazione trovata, o nil.
+//
+// Un gruppo può avere più di un vincolo impostato insieme (es. Exclusive e RequireOne per "esattamente una"): i vincoli sono verificati in ordine e il primo a fallire determina il Violation riportato da GroupError, indipendentemente dagli altri campi del gruppo.
+func (g *OptionGroup) validate() error {
+	var set []string
+	var missing []string
+	for _, o := range g.Opts {
+		if o == nil {
+			continue
+		}
+		if o.wasSet {
+			set = append(set, o.word)
+		} else {
+			missing = append(missing, o.word)
+		}
+	}
+	if g.RequireOne && len(set) == 0 {
+		return &GroupError{Group: g, Words: missing, Violation: ViolationRequireOne}
+	}
+	if g.Exclusive && len(set) > 1 {
+		return &GroupError{Group: g, Words: set, Violation: ViolationExclusive}
+	}
+	if g.RequireAll && len(missing) > 0 {
+		return &GroupError{Group: g, Words: missing, Violation: ViolationRequireAll}
+	}
+	return nil
+}
+
+// GroupError segnala la violazione di un vincolo di un OptionGroup. Violation indica quale vincolo è stato violato, vedi GroupViolation.
+type GroupError struct {
+	Group     *OptionGroup
+	Words     []string
+	Violation GroupViolation
+}
+
+// Error implementa l'interfaccia error.
+func (e *GroupError) Error() string {
+	switch e.Violation {
+	case ViolationRequireOne:
+		return fmt.Sprintf("one of %s is required in group '%s'", strings.Join(e.Words, ", "), e.Group.Name)
+	case ViolationRequireAll:
+		return fmt.Sprintf("options %s are required together in group '%s'", strings.Join(e.Words, ", "), e.Group.Name)
+	case ViolationExclusive:
+		return fmt.Sprintf("options %s are mutually exclusive in group '%s'", strings.Join(e.Words, ", "), e.Group.Name)
+	default:
+		return fmt.Sprintf("invalid option group '%s'", e.Group.Name)
+	}
+}
+
+// AppendOptGroup aggiunge un gruppo di opzioni al comando. Il vincolo è verificato da Parse dopo l'analisi della riga di comando.
+func (cmd *Command) AppendOptGroup(group *OptionGroup) {
+	if group != nil {
+		cmd.Groups = append(cmd.Groups, group)
+	}
+}
+
+// validateGroups verifica i gruppi di opzioni del comando e restituisce il primo GroupError riscontrato, o nil.
+func (cmd *Command) validateGroups() error {
+	for _, g := range cmd.Groups {
+		if g == nil {
+			continue
+		}
+		if err := g.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+IniConfigLoader è un ConfigLoader che legge un file INI, mappando ogni sezione alla parola di un comando e ogni chiave della sezione alla parola (senza il trattino iniziale) di un suo argomento o di una sua opzione.
+
+Si usa sia come clap.ActiveConfigLoader, nel qual caso interviene sugli argomenti e le opzioni con EnvVar o Default impostati (vedi Command.Prepare) e sugli argomenti richiesti assenti dalla riga di comando (vedi Parse), sia passandolo a LoadInto per precaricare direttamente tutti gli argomenti e le opzioni nominali di una CommandMap.
+*/
+type IniConfigLoader struct {
+	sections map[string]map[string]string
+}
+
+// LoadIniFile legge il file ini in path e restituisce l'IniConfigLoader corrispondente.
+func LoadIniFile(path string) (*IniConfigLoader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	loader := &IniConfigLoader{sections: make(map[string]map[string]string)}
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if loader.sections[section] == nil {
+			loader.sections[section] = make(map[string]string)
+		}
+		loader.sections[section][strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// Load implementa ConfigLoader: cerca word (senza il trattino iniziale) nella sezione intitolata come cmd.Word() (anch'essa senza trattino).
+func (l *IniConfigLoader) Load(cmd *Command, word string) (value string, ok bool) {
+	if cmd == nil {
+		return "", false
+	}
+	section, found := l.sections[trimDashes(cmd.Word())]
+	if !found {
+		return "", false
+	}
+	value, ok = section[trimDashes(word)]
+	return
+}
+
+func trimDashes(word string) string {
+	return strings.TrimLeft(word, "-")
+}
+
+/*
+EnvPrefix, se impostato, è anteposto (seguito da "_") al nome della variabile d'ambiente cercata da LoadInto per ogni comando e opzione, es. con EnvPrefix "MYPROG" l'opzione "-enc" del comando "-fix" è cercata come MYPROG_FIX_ENC.
+*/
+var EnvPrefix string
+
+/*
+LoadInto legge il file ini in path e scrive direttamente nei puntatori Value degli argomenti e delle opzioni nominali già registrati in ogni comando di cmdMap (tramite AppendNewArg/AppendNewOpt o il campo Prep), con lo schema sezione/chiave di IniConfigLoader.Load.
+
+Dopo il file, per ogni comando e opzione viene anche consultata la variabile d'ambiente "[EnvPrefix_]PAROLACOMANDO_PAROLAOPZIONE" (trattini iniziali rimossi, tutto maiuscolo), che ha precedenza sul file.
+La precedenza complessiva è: valori da riga di comando (applicati da Parse, eseguito dopo LoadInto) > variabili d'ambiente > file di configurazione > default incorporato nel programma.
+*/
+func LoadInto(cmdMap CommandMap, path string) error {
+	loader, err := LoadIniFile(path)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmdMap {
+		if cmd == nil {
+			continue
+		}
+		cmd.Prepare()
+		for _, arg := range cmd.Args {
+			if arg == nil || arg.IsGeneric() {
+				continue
+			}
+			var v string
+			var ok bool
+			if v, ok = envOverride(cmd, arg.word); !ok {
+				v, ok = loader.Load(cmd, arg.word)
+			}
+			if ok {
+				if err := arg.storeValue(v); err == nil {
+					arg.resolved = true
+				}
+			}
+		}
+		for _, opt := range cmd.Opts {
+			if opt == nil {
+				continue
+			}
+			var v string
+			var ok bool
+			if v, ok = envOverride(cmd, opt.word); !ok {
+				v, ok = loader.Load(cmd, opt.word)
+			}
+			if ok {
+				if b, perr := strconv.ParseBool(strings.ToLower(v)); perr == nil {
+					opt.storeValue(b)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// envOverride cerca la variabile d'ambiente "[EnvPrefix_]PAROLACOMANDO_PAROLAWORD" per cmd e word.
+func envOverride(cmd *Command, word string) (string, bool) {
+	name := strings.ToUpper(trimDashes(cmd.Word())) + "_" + strings.ToUpper(trimDashes(word))
+	if len(EnvPrefix) > 0 {
+		name = strings.ToUpper(EnvPrefix) + "_" + name
+	}
+	return os.LookupEnv(name)
+}
+
+/*
+ExtractConfigOption cerca in args un token "--config=path" (o "-config=path"), lo rimuove dalla sequenza e restituisce il percorso trovato.
+
+È pensato per essere chiamato prima di Parse, così che l'utente possa indicare un file di configurazione alternativo a quello di default:
+
+  args, cfgPath, ok := clap.ExtractConfigOption(os.Args[1:])
+  if ok {
+    clap.LoadInto(commands, cfgPath)
+  }
+  cmd, err := clap.Parse(args, commands, nil)
+*/
+func ExtractConfigOption(args []string) (remaining []string, path string, found bool) {
+	remaining = make([]string, 0, len(args))
+	for _, a := range args {
+		if v := strings.TrimPrefix(a, "--config="); v != a {
+			path, found = v, true
+			continue
+		}
+		if v := strings.TrimPrefix(a, "-config="); v != a {
+			path, found = v, true
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	return
+}
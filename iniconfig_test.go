@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadIntoThenParseRequiredArgFollowedByOption verifica che un argomento richiesto
+// già risolto da LoadInto (file ini) non faccia fallire Parse quando, sulla riga di
+// comando, è seguito da un'altra opzione: il bug era che match segnalava subito
+// "expected" non appena il token corrente non corrispondeva all'argomento, senza mai
+// considerare che il valore fosse già stato precaricato.
+func TestLoadIntoThenParseRequiredArgFollowedByOption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mytool.ini")
+	if err := os.WriteFile(path, []byte("[fix]\nenc = utf8\n"), 0644); err != nil {
+		t.Fatalf("scrittura file ini: %v", err)
+	}
+
+	var input, output, encoding string
+	var overwrite bool
+	cmdFix := NewCommand("-fix", "Fix", "Sistema la codifica.")
+	cmdFix.Prep = func(c *Command) {
+		c.AppendNewArg("", "input", true, &input, "file sorgente")
+		c.AppendNewArg("", "output", true, &output, "file destinazione")
+		c.AppendNewArg("-enc", "encoding", true, &encoding, "codifica")
+		c.AppendNewOpt("-w", "overwrite", &overwrite, "sovrascrivi")
+	}
+
+	cmds := NewCommandMap(1)
+	cmds.Insert(cmdFix)
+
+	if err := LoadInto(cmds, path); err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+
+	cmd, err := Parse([]string{"-fix", "in.txt", "out.txt", "-w"}, cmds, nil)
+	if err != nil {
+		t.Fatalf("Parse: errore inatteso %v", err)
+	}
+	if cmd != cmdFix {
+		t.Fatalf("Parse: comando inatteso %v", cmd)
+	}
+	if input != "in.txt" || output != "out.txt" {
+		t.Fatalf("Parse: input/output inattesi: %q/%q", input, output)
+	}
+	if encoding != "utf8" {
+		t.Fatalf("encoding atteso 'utf8' (da LoadInto), trovato %q", encoding)
+	}
+	if !overwrite {
+		t.Fatalf("overwrite atteso true")
+	}
+}
+
+// TestPrepareFallbackThenParseRequiredArgFollowedByOption verifica lo stesso scenario
+// con il precaricamento tramite EnvVar (Command.Prepare/preloadFallbacks) invece di
+// LoadInto, per assicurarsi che la correzione copra entrambi i percorsi.
+func TestPrepareFallbackThenParseRequiredArgFollowedByOption(t *testing.T) {
+	t.Setenv("ENC", "utf8")
+
+	var input, output, encoding string
+	var overwrite bool
+	cmdFix := NewCommand("-fix", "Fix", "Sistema la codifica.")
+	cmdFix.Prep = func(c *Command) {
+		c.AppendNewArg("", "input", true, &input, "file sorgente")
+		c.AppendNewArg("", "output", true, &output, "file destinazione")
+		arg := c.AppendNewArg("-enc", "encoding", true, &encoding, "codifica")
+		arg.EnvVar = "ENC"
+		c.AppendNewOpt("-w", "overwrite", &overwrite, "sovrascrivi")
+	}
+
+	cmds := NewCommandMap(1)
+	cmds.Insert(cmdFix)
+
+	cmd, err := Parse([]string{"-fix", "in.txt", "out.txt", "-w"}, cmds, nil)
+	if err != nil {
+		t.Fatalf("Parse: errore inatteso %v", err)
+	}
+	if cmd != cmdFix {
+		t.Fatalf("Parse: comando inatteso %v", cmd)
+	}
+	if encoding != "utf8" {
+		t.Fatalf("encoding atteso 'utf8' (da ENC), trovato %q", encoding)
+	}
+	if !overwrite {
+		t.Fatalf("overwrite atteso true")
+	}
+}
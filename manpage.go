@@ -0,0 +1,163 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+synopsis assembla la riga SYNOPSIS di cmd nello stesso ordine "comando argomenti [opzionali] [opzioni]" imposto da Parse.
+*/
+func (cmd *Command) synopsis() string {
+	parts := make([]string, 0, 3)
+	parts = append(parts, cmd.word)
+	if line, _ := cmd.Args.HelpStrings(0); len(line) > 0 {
+		parts = append(parts, line)
+	}
+	if line, _ := cmd.Opts.HelpStrings(0); len(line) > 0 {
+		parts = append(parts, line)
+	}
+	return strings.Join(parts, " ")
+}
+
+// manSection restituisce cmd.ManSection, o 1 se non impostato (o non positivo).
+func (cmd *Command) manSection() int {
+	if cmd.ManSection <= 0 {
+		return 1
+	}
+	return cmd.ManSection
+}
+
+// progName restituisce il nome del programma usato nei nomi dei file e nelle intestazioni generate, ricavato da os.Args[0].
+func progName() string {
+	if len(os.Args) == 0 {
+		return "clap"
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// sanitizeCompletionName sostituisce i caratteri non ammessi nel nome di una funzione/file generati da GenerateMan e GenerateMarkdown.
+func sanitizeCompletionName(word string) string {
+	r := strings.NewReplacer("-", "_", "=", "_", " ", "_")
+	return r.Replace(word)
+}
+
+/*
+GenerateMan scrive in dir una pagina di manuale roff per ogni comando di cm, nominata "<progname>-<parola>.<sezione>", con SYNOPSIS, DESCRIPTION, ARGUMENTS, OPTIONS e, se Command.SeeAlso è impostato, SEE ALSO.
+
+La sezione del manuale è Command.ManSection, 1 se non impostato. Il nome del programma è ricavato da os.Args[0].
+*/
+func (cm CommandMap) GenerateMan(dir string) error {
+	prog := progName()
+	for _, k := range cm.visibleKeys() {
+		cmd := cm[k]
+		cmd.Prepare()
+		section := cmd.manSection()
+		name := fmt.Sprintf("%s-%s", prog, sanitizeCompletionName(trimDashes(cmd.word)))
+		path := filepath.Join(dir, fmt.Sprintf("%s.%d", name, section))
+		if err := os.WriteFile(path, []byte(cmd.renderMan(prog, name, section)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderMan genera il testo roff della pagina di manuale di cmd.
+func (cmd *Command) renderMan(prog string, name string, section int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s %d \"%s\"\n", strings.ToUpper(name), section, time.Now().Format("January 2006"))
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", name, cmd.Help)
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s %s\n", prog, cmd.synopsis())
+	b.WriteString(".SH DESCRIPTION\n")
+	fmt.Fprintf(&b, "%s\n", cmd.Help)
+	if len(cmd.Args) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, a := range cmd.Args {
+			if a == nil {
+				continue
+			}
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", a.Describe(false, true), a.Help)
+		}
+	}
+	if len(cmd.Opts) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, o := range cmd.Opts {
+			if o == nil {
+				continue
+			}
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s (default: %s)\n", o.Describe(false, false, false), o.Help, strconv.FormatBool(o.MatchValue))
+		}
+	}
+	if len(cmd.SeeAlso) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		items := make([]string, len(cmd.SeeAlso))
+		for i, s := range cmd.SeeAlso {
+			items[i] = fmt.Sprintf("%s-%s(%d)", prog, sanitizeCompletionName(trimDashes(s)), section)
+		}
+		fmt.Fprintf(&b, "%s\n", strings.Join(items, ", "))
+	}
+	return b.String()
+}
+
+/*
+GenerateMarkdown scrive in dir un file "<progname>-<parola>.md" per ogni comando di cm, con lo stesso contenuto di GenerateMan reso in Markdown.
+*/
+func (cm CommandMap) GenerateMarkdown(dir string) error {
+	prog := progName()
+	for _, k := range cm.visibleKeys() {
+		cmd := cm[k]
+		cmd.Prepare()
+		name := fmt.Sprintf("%s-%s", prog, sanitizeCompletionName(trimDashes(cmd.word)))
+		path := filepath.Join(dir, name+".md")
+		if err := os.WriteFile(path, []byte(cmd.renderMarkdown(prog, name)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderMarkdown genera il testo Markdown della pagina di documentazione di cmd.
+func (cmd *Command) renderMarkdown(prog string, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", name)
+	fmt.Fprintf(&b, "%s\n\n", cmd.Help)
+	b.WriteString("### Synopsis\n\n")
+	fmt.Fprintf(&b, "\t%s %s\n\n", prog, cmd.synopsis())
+	if len(cmd.Args) > 0 {
+		b.WriteString("### Arguments\n\n")
+		for _, a := range cmd.Args {
+			if a == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "* `%s` - %s\n", a.Describe(false, true), a.Help)
+		}
+		b.WriteString("\n")
+	}
+	if len(cmd.Opts) > 0 {
+		b.WriteString("### Options\n\n")
+		for _, o := range cmd.Opts {
+			if o == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "* `%s` - %s (default: %s)\n", o.Describe(false, false, false), o.Help, strconv.FormatBool(o.MatchValue))
+		}
+		b.WriteString("\n")
+	}
+	if len(cmd.SeeAlso) > 0 {
+		b.WriteString("### See Also\n\n")
+		for _, s := range cmd.SeeAlso {
+			seeName := fmt.Sprintf("%s-%s", prog, sanitizeCompletionName(trimDashes(s)))
+			fmt.Fprintf(&b, "* [%s](%s.md)\n", seeName, seeName)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
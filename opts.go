@@ -33,6 +33,10 @@ type Option struct {
 	id string
 	//word rappresenta l'opzione nella linea di comando.
 	word string
+	//ShortWord è la forma breve dell'opzione, es. "-v". Vuota se non usata.
+	ShortWord string
+	//LongWord è la forma lunga dell'opzione, es. "--verbose". Vuota se non usata.
+	LongWord string
 	//Args contiene gli argomenti dell'opzione.
 	Args ArgumentList
 	//MatchValue è il valore assegnato all'opzione quando presente senza valore.
@@ -41,6 +45,12 @@ type Option struct {
 	Value *bool
 	//Help contiene la stringa che descrive l'argomento.
 	Help string
+	//EnvVar, se impostato, è il nome della variabile d'ambiente consultata quando l'opzione non è specificata in riga di comando.
+	EnvVar string
+	//Default è il valore testuale ("true"/"false" ecc., vedi Option) usato quando l'opzione non è specificata né in riga di comando né tramite EnvVar o ConfigLoader.
+	Default string
+	//wasSet indica se l'opzione è stata trovata in riga di comando, usato da OptionGroup per verificare i vincoli fra opzioni.
+	wasSet bool
 }
 
 /*
@@ -54,6 +64,25 @@ func NewOption(optWord string, optID string, optValue *bool, optHelp string) *Op
 	return &Option{word: optWord, Value: optValue, MatchValue: true, Help: optHelp}
 }
 
+/*
+NewShortLongOption crea un'opzione con forma breve e forma lunga, es. "-v" e "--verbose".
+
+Almeno una delle due parole deve essere specificata. MatchValue è impostato su true.
+La parola restituita da Word è la forma lunga se presente, altrimenti quella breve, per compatibilità con il codice che usa solo word.
+*/
+func NewShortLongOption(shortWord string, longWord string, optID string, optValue *bool, optHelp string) *Option {
+	shortWord = strings.TrimSpace(shortWord)
+	longWord = strings.TrimSpace(longWord)
+	if len(shortWord) == 0 && len(longWord) == 0 {
+		return nil
+	}
+	word := longWord
+	if len(word) == 0 {
+		word = shortWord
+	}
+	return &Option{id: optID, word: word, ShortWord: shortWord, LongWord: longWord, Value: optValue, MatchValue: true, Help: optHelp}
+}
+
 //ID restituisce l'identificativo dell'opzione.
 func (opt *Option) ID() string {
 	return opt.id
@@ -64,6 +93,19 @@ func (opt *Option) Word() string {
 	return opt.word
 }
 
+//words restituisce tutte le parole valide per l'opzione (word, ShortWord, LongWord), senza duplicati e senza stringhe vuote.
+func (opt *Option) words() []string {
+	seen := make(map[string]bool, 3)
+	words := make([]string, 0, 3)
+	for _, w := range []string{opt.word, opt.ShortWord, opt.LongWord} {
+		if len(w) > 0 && !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
 //AppendArg aggiunge un argomento alla lista argomenti dell'opzione.
 func (opt *Option) AppendArg(arg *Argument) {
 	if arg != nil {
@@ -82,9 +124,14 @@ func (opt *Option) AppendNewArg(argWord string, argID string, argRequired bool,
 }
 
 //Describe restituisce una stringa che descrive la sintassi dell'opzione.
+//Se sono impostate sia ShortWord che LongWord, la sintassi è "-o, --opt".
 func (opt *Option) Describe(showOptional bool, showValue bool, showArgs bool) string {
 	var str string
-	str = opt.word
+	if len(opt.ShortWord) > 0 && len(opt.LongWord) > 0 {
+		str = opt.ShortWord + ", " + opt.LongWord
+	} else {
+		str = opt.word
+	}
 	if showValue {
 		str += "[=bool]"
 	}
@@ -100,29 +147,55 @@ func (opt *Option) Describe(showOptional bool, showValue bool, showArgs bool) st
 }
 
 //match verifica che la stringa passata corrisponde all'opzione e memorizza il valore.
+//Riconosce la parola nuda (es. "-o", "--opt"), la sintassi con valore "parola=bool" (es. "--opt=true", "-o=1") e, per i bundle di opzioni booleane brevi, viene invocata già sul singolo token espanso da expandShortBundle.
 func (opt *Option) match(s string) (result bool, err error) {
-	//verifica presenza opzione
-	if s == opt.word {
-		opt.storeValue(opt.MatchValue)
-		return true, nil
+	//verifica presenza opzione, in una qualsiasi delle sue forme (word, ShortWord, LongWord)
+	for _, w := range opt.words() {
+		if s == w {
+			opt.storeValue(opt.MatchValue)
+			opt.wasSet = true
+			return true, nil
+		}
 	}
 	// argomento con verb e valore
 	//verifica l'impostazione del valore per l'opzione
-	//es. word=true
-	if val := strings.TrimPrefix(s, (opt.word + "=")); val != s {
+	//es. word=true, --opt=true, -o=true
+	var val string
+	var ok bool
+	for _, w := range opt.words() {
+		if v := strings.TrimPrefix(s, (w + "=")); v != s {
+			val, ok = v, true
+			break
+		}
+	}
+	if ok {
 		result = true
-		switch strings.ToLower(val) {
-		case "true", "t", "1":
-			opt.storeValue(true)
-		case "false", "f", "0":
-			opt.storeValue(false)
-		default:
+		if b, valid := parseBoolToken(val); valid {
+			opt.storeValue(b)
+			opt.wasSet = true
+		} else {
 			err = fmt.Errorf("not valid value '%s' for option %s", val, opt.word)
 		}
 	}
 	return
 }
 
+/*
+parseBoolToken analizza s come valore booleano con la stessa sintassi di "parola=bool" (vedi Option), cioè true/t/1 o false/f/0, senza distinguere maiuscole e minuscole.
+
+È usata sia da match per la forma "parola=bool" sia dal ciclo di analisi di Parse per la forma a token successivo delle opzioni lunghe ("--opt value").
+*/
+func parseBoolToken(s string) (value bool, ok bool) {
+	switch strings.ToLower(s) {
+	case "true", "t", "1":
+		return true, true
+	case "false", "f", "0":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
 //storeValue memorizza il valore dell'opzione.
 func (opt *Option) storeValue(b bool) {
 	if opt.Value != nil {
@@ -150,10 +223,11 @@ func (ol OptionList) HelpStrings(listIndent int) (line string, list string) {
 	for _, o := range ol {
 		if o != nil {
 			items = append(items, o.Describe(true, false, false))
+			extra := envDefaultSuffix(o.EnvVar, o.Default)
 			if len(o.Help) > 0 {
-				list += fmt.Sprintf("\n%[1]s%[2]s\n  %[1]s%[3]s\n", indent, o.Describe(false, true, true), o.Help)
+				list += fmt.Sprintf("\n%[1]s%[2]s%[4]s\n  %[1]s%[3]s\n", indent, o.Describe(false, true, true), o.Help, extra)
 			} else {
-				list += fmt.Sprintf("\n%s%s\n", indent, o.Describe(false, true, true))
+				list += fmt.Sprintf("\n%s%s%s\n", indent, o.Describe(false, true, true), extra)
 			}
 			if len(o.Args) > 0 {
 				if _, alst := o.Args.HelpStrings(listIndent + 3); len(alst) > 0 {
@@ -168,6 +242,34 @@ func (ol OptionList) HelpStrings(listIndent int) (line string, list string) {
 	return
 }
 
+/*
+expandShortBundle prova a interpretare s come un bundle di opzioni brevi booleane in stile POSIX, es. "-xvf" diventa ["-x", "-v", "-f"].
+
+Restituisce nil se s non inizia con un singolo "-" (non "--"), se è più corto di tre caratteri o se una qualsiasi delle lettere non corrisponde a ShortWord di un'opzione senza argomenti propri.
+*/
+func (ol OptionList) expandShortBundle(s string) []string {
+	if !strings.HasPrefix(s, "-") || strings.HasPrefix(s, "--") || len(s) < 3 {
+		return nil
+	}
+	letters := s[1:]
+	tokens := make([]string, 0, len(letters))
+	for _, r := range letters {
+		short := "-" + string(r)
+		var found *Option
+		for _, o := range ol {
+			if o != nil && o.ShortWord == short {
+				found = o
+				break
+			}
+		}
+		if found == nil || len(found.Args) > 0 {
+			return nil
+		}
+		tokens = append(tokens, short)
+	}
+	return tokens
+}
+
 // =======================================================
 
 //optListEnum gestisce un oggetto OptionList per l'enumerazione.
@@ -0,0 +1,236 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+Tokenize divide line negli argomenti che Parse si aspetta, rispettando le stringhe fra virgolette singole o doppie e l'escape con backslash (solo dentro le virgolette doppie e fuori da esse).
+
+È usata dal REPL per trasformare una riga digitata nello stesso slice di stringhe che altrimenti arriverebbe da os.Args.
+*/
+func Tokenize(line string) []string {
+	var tokens []string
+	var cur strings.Builder
+	var inQuote rune
+	started := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case inQuote != 0:
+			if c == '\\' && inQuote == '"' && i+1 < len(runes) {
+				i++
+				cur.WriteRune(runes[i])
+				continue
+			}
+			if c == inQuote {
+				inQuote = 0
+				continue
+			}
+			cur.WriteRune(c)
+		case c == '\'' || c == '"':
+			inQuote = c
+			started = true
+		case c == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			started = true
+		case c == ' ' || c == '\t':
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(c)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens
+}
+
+/*
+Readline è l'interfaccia per la lettura di una riga di input con eventuale editing e history.
+
+Un chiamante può iniettare un'implementazione basata su golang.org/x/term o chzyer/readline; se REPL.Readline non è impostato viene usata una lettura semplice riga per riga.
+*/
+type Readline interface {
+	// Readline legge e restituisce una riga, senza l'a-capo finale. Restituisce io.EOF quando l'input termina.
+	Readline() (string, error)
+}
+
+// lineReader è il Readline di default, legge linee da un bufio.Scanner senza editing né history.
+type lineReader struct {
+	scanner *bufio.Scanner
+}
+
+func (lr *lineReader) Readline() (string, error) {
+	if !lr.scanner.Scan() {
+		if err := lr.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return lr.scanner.Text(), nil
+}
+
+// MetaCommand è la funzione eseguita per un meta-comando REPL, cioè una riga che inizia con ":".
+type MetaCommand func(args []string) error
+
+/*
+REPL esegue un ciclo interattivo di lettura-analisi-esecuzione su una CommandMap esistente, alla maniera delle shell che espongono comandi speciali come quelli di gomacro.
+
+Una riga che inizia con ":" è risolta come meta-comando tramite RegisterMeta (es. ":help", ":quit", ":set prompt=...") oppure, per ":source file", gestita internamente (vedi dispatchMeta), altrimenti è tokenizzata con Tokenize ed eseguita tramite Parse e Command.Execute.
+OnError, se impostato, decide se continuare il ciclo dopo un errore di analisi: restituendo true il REPL stampa l'errore e prosegue, restituendo false il ciclo si interrompe restituendo quell'errore.
+*/
+type REPL struct {
+	Commands CommandMap
+	Default  *Command
+	Prompt   string
+	Readline Readline
+	Out      io.Writer
+	OnError  func(error) bool
+	metas    map[string]MetaCommand
+	metaHelp map[string]string
+	quit     bool
+}
+
+// NewREPL crea un REPL che riconosce ed esegue i comandi di cmdMap, usando cmdDefault come comando di default per Parse.
+func NewREPL(cmdMap CommandMap, cmdDefault *Command) *REPL {
+	r := &REPL{Commands: cmdMap, Default: cmdDefault, Prompt: "> ", metas: make(map[string]MetaCommand), metaHelp: make(map[string]string)}
+	r.RegisterMeta("quit", func(args []string) error {
+		r.quit = true
+		return nil
+	}, "esce dal REPL")
+	r.RegisterMeta("help", func(args []string) error {
+		for name, help := range r.metaHelp {
+			fmt.Fprintf(r.Out, ":%s\t%s\n", name, help)
+		}
+		return nil
+	}, "mostra questo elenco")
+	r.RegisterMeta("set", func(args []string) error {
+		for _, a := range args {
+			if v := strings.TrimPrefix(a, "prompt="); v != a {
+				r.Prompt = v
+			}
+		}
+		return nil
+	}, "imposta un'opzione del REPL, es. ':set prompt=> '")
+	return r
+}
+
+// RegisterMeta registra un meta-comando, richiamato quando una riga del REPL inizia con ":" + name.
+func (r *REPL) RegisterMeta(name string, fn MetaCommand, help string) {
+	r.metas[name] = fn
+	r.metaHelp[name] = help
+}
+
+// Run avvia il ciclo REPL leggendo da in (se Readline non è impostato) e scrivendo il prompt e l'output su out, fino a io.EOF o a un ":quit".
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	r.Out = out
+	if r.Readline == nil {
+		r.Readline = &lineReader{scanner: bufio.NewScanner(in)}
+	}
+	r.quit = false
+	for !r.quit {
+		fmt.Fprint(out, r.Prompt)
+		line, err := r.Readline.Readline()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := r.dispatch(line); err != nil {
+			if r.OnError == nil || !r.OnError(err) {
+				return err
+			}
+			fmt.Fprintln(out, err)
+		}
+	}
+	return nil
+}
+
+// dispatch esegue una riga: un meta-comando se inizia con ":", altrimenti la tokenizza e la esegue tramite Parse.
+func (r *REPL) dispatch(line string) error {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(line, ":") {
+		return r.dispatchMeta(line[1:])
+	}
+	return r.execute(line)
+}
+
+// dispatchMeta risolve e invoca il meta-comando indicato da line (senza il ":" iniziale). ":source file" è gestito internamente.
+func (r *REPL) dispatchMeta(line string) error {
+	fields := Tokenize(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	name, args := fields[0], fields[1:]
+	if name == "source" && len(args) == 1 {
+		return r.sourceFile(args[0])
+	}
+	fn, ok := r.metas[name]
+	if !ok {
+		return fmt.Errorf("unknown meta command ':%s'", name)
+	}
+	return fn(args)
+}
+
+// sourceFile esegue riga per riga lo script path, come se ogni riga fosse stata digitata nel REPL.
+func (r *REPL) sourceFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := r.dispatch(scanner.Text()); err != nil {
+			if r.OnError == nil || !r.OnError(err) {
+				return err
+			}
+			fmt.Fprintln(r.Out, err)
+		}
+	}
+	return scanner.Err()
+}
+
+/*
+execute tokenizza line ed esegue il comando individuato tramite Parse.
+
+Prima di ogni Parse azzera lo stato delle opzioni di tutti i comandi (vedi Command.resetOptions): a differenza di un programma a riga di comando, che processa un solo Parse per esecuzione, il REPL riusa sempre gli stessi oggetti Command/Option fra una riga e l'altra, quindi wasSet e i valori booleani di una riga precedente non devono sopravvivere alla successiva.
+*/
+func (r *REPL) execute(line string) error {
+	args := Tokenize(line)
+	r.Commands.resetOptions()
+	if r.Default != nil {
+		r.Default.resetOptions()
+	}
+	cmd, err := Parse(args, r.Commands, r.Default)
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		cmd.Execute()
+	}
+	return nil
+}
+
+// RunREPL è un metodo di comodo che crea un REPL su cm e lo avvia subito, leggendo da in e scrivendo su out.
+func (cm CommandMap) RunREPL(in io.Reader, out io.Writer) error {
+	return NewREPL(cm, nil).Run(in, out)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestREPLResetsOptionStateBetweenCommands verifica che un'opzione booleana impostata
+// in una riga del REPL non sopravviva alla riga successiva che non la specifica: il bug
+// era che Option.wasSet e il valore puntato da Value non venivano mai azzerati fra un
+// dispatch e l'altro, perché il REPL riusa sempre lo stesso oggetto Command/Option.
+func TestREPLResetsOptionStateBetweenCommands(t *testing.T) {
+	var overwrite bool
+	var seen []bool
+	cmdFix := NewCommand("-fix", "Fix", "Sistema la codifica.")
+	cmdFix.Prep = func(c *Command) {
+		var input, output string
+		c.AppendNewArg("", "input", true, &input, "file sorgente")
+		c.AppendNewArg("", "output", true, &output, "file destinazione")
+		c.AppendNewOpt("-w", "overwrite", &overwrite, "sovrascrivi")
+	}
+	cmdFix.Exec = func(data interface{}) {
+		seen = append(seen, overwrite)
+	}
+
+	cmds := NewCommandMap(1)
+	cmds.Insert(cmdFix)
+
+	r := NewREPL(cmds, nil)
+	in := strings.NewReader("-fix a.txt b.txt -w\n-fix c.txt d.txt\n:quit\n")
+	var out bytes.Buffer
+	if err := r.Run(in, &out); err != nil {
+		t.Fatalf("Run: errore inatteso %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("attese 2 esecuzioni, trovate %d", len(seen))
+	}
+	if !seen[0] {
+		t.Fatalf("prima esecuzione: overwrite atteso true")
+	}
+	if seen[1] {
+		t.Fatalf("seconda esecuzione: overwrite atteso false, è rimasto true dalla riga precedente")
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"rmite/clap/completion"
+)
+
+// CompletionHintKind seleziona il genere di completamento dinamico proposto da Argument.CompletionHint quando Values e Func non sono impostati.
+type CompletionHintKind int
+
+const (
+	// NoCompletionHint è il valore zero, nessun completamento dinamico.
+	NoCompletionHint CompletionHintKind = iota
+	// CompleteFile propone il completamento dei nomi di file, delegato alla shell (es. "compgen -f").
+	CompleteFile
+	// CompleteDir propone il completamento dei nomi di directory, delegato alla shell (es. "compgen -d").
+	CompleteDir
+)
+
+/*
+CompletionHint configura il completamento dinamico del valore di un argomento, usato dai generatori di completamento e dal comando nascosto registrato da CommandMap.EnableCompletion.
+
+Il valore zero (tutti i campi a zero) significa nessun hint, per compatibilità con gli argomenti esistenti.
+Kind seleziona CompleteFile o CompleteDir; in alternativa Values propone un elenco fisso di proposte, oppure Func le genera dinamicamente a partire dal prefisso già digitato. Se più campi sono impostati, l'ordine di precedenza è Func, poi Values, poi Kind.
+*/
+type CompletionHint struct {
+	Kind   CompletionHintKind
+	Values []string
+	Func   CompleteFunc
+}
+
+// completions restituisce le proposte di completamento per l'argomento, dato il prefisso già digitato.
+func (arg *Argument) completions(prefix string) []string {
+	switch {
+	case arg.CompletionHint.Func != nil:
+		return arg.CompletionHint.Func(prefix)
+	case len(arg.CompletionHint.Values) > 0:
+		return filterByPrefix(arg.CompletionHint.Values, prefix)
+	case arg.CompleteFunc != nil:
+		return arg.CompleteFunc(prefix)
+	}
+	if cv, ok := arg.Value.(interface{ Choices() []string }); ok {
+		return filterByPrefix(cv.Choices(), prefix)
+	}
+	return nil
+}
+
+func filterByPrefix(values []string, prefix string) []string {
+	if len(prefix) == 0 {
+		return values
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+/*
+commandSpecs raccoglie da cm le informazioni usate dal package completion per generare gli script, escludendo i comandi interni (vedi Command.internal).
+
+Se cmdDefault è diverso da nil e non è già presente in cm (vedi l'introduzione del package per il comando default di Parse), la sua parola è inclusa fra quelle completate, così come le sue opzioni una volta digitata.
+*/
+func (cm CommandMap) commandSpecs(cmdDefault *Command) []completion.CommandSpec {
+	keys := cm.visibleKeys()
+	specs := make([]completion.CommandSpec, 0, len(keys)+1)
+	for _, k := range keys {
+		cmd := cm[k]
+		specs = append(specs, completion.CommandSpec{Word: cmd.word, Opts: cmd.optionWords(), Choices: cmd.argChoices(), Args: cmd.argHints()})
+	}
+	if cmdDefault != nil {
+		if _, ok := cm[cmdDefault.word]; !ok {
+			specs = append(specs, completion.CommandSpec{Word: cmdDefault.word, Opts: cmdDefault.optionWords(), Choices: cmdDefault.argChoices(), Args: cmdDefault.argHints()})
+		}
+	}
+	return specs
+}
+
+/*
+argHints raccoglie, per ogni argomento nominale del comando con un CompletionHint o un CompleteFunc impostato, l'hint da passare al generatore di completamento (vedi completion.ArgSpec).
+
+CompletionHint.Func e CompletionHint.Values (così come il più vecchio Argument.CompleteFunc) producono HintDynamic: lo script generato invoca il comando nascosto "__complete" (vedi EnableCompletion/resolveCompletion) perché solo a runtime è possibile calcolare le proposte.
+*/
+func (cmd *Command) argHints() []completion.ArgSpec {
+	var specs []completion.ArgSpec
+	for _, a := range cmd.Args {
+		if a == nil || a.IsGeneric() {
+			continue
+		}
+		var hint completion.ArgHintKind
+		switch {
+		case a.CompletionHint.Func != nil, len(a.CompletionHint.Values) > 0, a.CompleteFunc != nil:
+			hint = completion.HintDynamic
+		case a.CompletionHint.Kind == CompleteFile:
+			hint = completion.HintFile
+		case a.CompletionHint.Kind == CompleteDir:
+			hint = completion.HintDir
+		default:
+			continue
+		}
+		specs = append(specs, completion.ArgSpec{Word: a.word, Hint: hint})
+	}
+	return specs
+}
+
+// GenerateBashCompletion scrive su w lo script di completamento bash per progName, coprendo tutti i comandi della mappa e, se impostato, il comando default usato da Parse (vedi commandSpecs).
+func (cm CommandMap) GenerateBashCompletion(w io.Writer, progName string, cmdDefault *Command) error {
+	_, err := io.WriteString(w, completion.RenderBash(progName, cm.commandSpecs(cmdDefault)))
+	return err
+}
+
+// GenerateZshCompletion scrive su w lo script di completamento zsh per progName.
+func (cm CommandMap) GenerateZshCompletion(w io.Writer, progName string, cmdDefault *Command) error {
+	_, err := io.WriteString(w, completion.RenderZsh(progName, cm.commandSpecs(cmdDefault)))
+	return err
+}
+
+// GenerateFishCompletion scrive su w lo script di completamento fish per progName.
+func (cm CommandMap) GenerateFishCompletion(w io.Writer, progName string, cmdDefault *Command) error {
+	_, err := io.WriteString(w, completion.RenderFish(progName, cm.commandSpecs(cmdDefault)))
+	return err
+}
+
+// GeneratePowerShellCompletion scrive su w lo script di completamento PowerShell per progName.
+func (cm CommandMap) GeneratePowerShellCompletion(w io.Writer, progName string, cmdDefault *Command) error {
+	_, err := io.WriteString(w, completion.RenderPowerShell(progName, cm.commandSpecs(cmdDefault)))
+	return err
+}
+
+// completionRequest raccoglie gli argomenti del comando nascosto "__complete" registrato da EnableCompletion.
+type completionRequest struct {
+	cmdWord string
+	argWord string
+	prefix  string
+}
+
+/*
+EnableCompletion registra in cm un comando nascosto "__complete" che i generatori di completamento dinamico possono invocare a runtime (es. "progName __complete -fix -enc U", con la parola del comando e quella dell'argomento), per ottenere le proposte di un argomento il cui CompletionHint non può essere risolto staticamente dalla shell.
+
+Il comando stampa una proposta per riga su stdout e non richiede che sia inserito nell'help: non è pensato per essere digitato dall'utente.
+*/
+func (cm CommandMap) EnableCompletion(progName string) {
+	hidden := NewCommand("__complete", "", "completamento dinamico, uso interno, invocato dagli script generati da Generate*Completion")
+	hidden.internal = true
+	hidden.Prep = func(c *Command) {
+		req := &completionRequest{}
+		c.AppendNewArg("", "cmd", true, &req.cmdWord, "comando da completare")
+		c.AppendNewArg("", "arg", true, &req.argWord, "parola dell'argomento da completare")
+		c.AppendNewArg("", "prefix", false, &req.prefix, "prefisso già digitato")
+		c.Data = req
+	}
+	hidden.Exec = func(data interface{}) {
+		req, ok := data.(*completionRequest)
+		if !ok {
+			return
+		}
+		for _, s := range cm.resolveCompletion(req.cmdWord, req.argWord, req.prefix) {
+			fmt.Println(s)
+		}
+	}
+	cm.Insert(hidden)
+}
+
+// resolveCompletion calcola le proposte di completamento per l'argomento argWord (del comando stesso o di una sua opzione) del comando cmdWord.
+func (cm CommandMap) resolveCompletion(cmdWord string, argWord string, prefix string) []string {
+	cmd, ok := cm[cmdWord]
+	if !ok {
+		return nil
+	}
+	cmd.Prepare()
+	for _, a := range cmd.Args {
+		if a != nil && a.word == argWord {
+			return a.completions(prefix)
+		}
+	}
+	for _, o := range cmd.Opts {
+		if o == nil {
+			continue
+		}
+		for _, a := range o.Args {
+			if a != nil && a.word == argWord {
+				return a.completions(prefix)
+			}
+		}
+	}
+	return nil
+}
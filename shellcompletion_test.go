@@ -0,0 +1,39 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestGenerateBashCompletionUsesCompletionHint verifica che gli argomenti con
+// CompletionHint (CompleteFile o Values) compaiano nello script bash generato:
+// il bug era che commandSpecs non portava alcuna informazione sugli argomenti,
+// quindi CompletionHint era completamente ignorato dai generatori.
+func TestGenerateBashCompletionUsesCompletionHint(t *testing.T) {
+	cmdFix := NewCommand("-fix", "Fix", "Sistema la codifica.")
+	var path string
+	var mode string
+	arg := cmdFix.AppendNewArg("-out", "output", false, &path, "file destinazione")
+	arg.CompletionHint = CompletionHint{Kind: CompleteFile}
+	dyn := cmdFix.AppendNewArg("-mode", "mode", false, &mode, "modalità")
+	dyn.CompletionHint = CompletionHint{Values: []string{"fast", "slow"}}
+
+	cmds := NewCommandMap(1)
+	cmds.Insert(cmdFix)
+
+	var buf bytes.Buffer
+	if err := cmds.GenerateBashCompletion(&buf, "mytool", nil); err != nil {
+		t.Fatalf("GenerateBashCompletion: %v", err)
+	}
+	script := buf.String()
+
+	if !strings.Contains(script, "-out=*)") || !strings.Contains(script, "compgen -f") {
+		t.Fatalf("script atteso con il completamento file per -out, trovato:\n%s", script)
+	}
+	if !strings.Contains(script, "-mode=*)") || !strings.Contains(script, "__complete -fix -mode") {
+		t.Fatalf("script atteso con l'invocazione di __complete per -mode, trovato:\n%s", script)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2017 Renato Mastrulli. Tutti i diritti riservati. All rights reserved.
+
+package clap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+ValueParser è l'interfaccia per un parser di valori personalizzato, alternativa ad ArgumentStore e StoreValue per i tipi non previsti dallo switch di storeValue (durate, indirizzi IP, time.Time, dimensioni in byte, percorsi, espressioni regolari, ecc.).
+
+Parse analizza str e memorizza il valore, restituendo l'eventuale errore.
+String restituisce la rappresentazione testuale del valore corrente, utile per l'help.
+Type restituisce un nome breve del tipo, utile per l'help e per i generatori di completamento.
+
+Un parser personalizzato si usa passando un'istanza a NewArg come argValue (vedi durationValue in bindstruct.go per un esempio): non esiste (più) un registro di ValueParser indicizzato per nome, che avrebbe richiesto una factory in grado di creare l'istanza e collegarla a un puntatore di destinazione scelto dal chiamante, cosa che l'interfaccia ValueParser da sola non permette di fare in modo generico. Il registro iniziale (RegisterValueParser/LookupValueParser) è stato rimosso perché non aveva alcun consumatore: né BindStruct né storeValue lo consultavano.
+*/
+type ValueParser interface {
+	Parse(str string) error
+	String() string
+	Type() string
+}
+
+// =======================================================
+
+// choiceValue è il ValueParser usato da NewChoiceArg, accetta solo uno fra i valori di choices.
+type choiceValue struct {
+	dest    *string
+	choices []string
+	label   string
+}
+
+// Parse verifica che str sia uno dei valori ammessi e lo memorizza in dest.
+func (c *choiceValue) Parse(str string) error {
+	for _, ch := range c.choices {
+		if ch == str {
+			*c.dest = str
+			return nil
+		}
+	}
+	return fmt.Errorf("value '%s' for %s must be one of [%s]", str, c.label, strings.Join(c.choices, "|"))
+}
+
+// String restituisce il valore corrente.
+func (c *choiceValue) String() string {
+	if c.dest != nil {
+		return *c.dest
+	}
+	return ""
+}
+
+// Type restituisce "choice".
+func (c *choiceValue) Type() string {
+	return "choice"
+}
+
+// Choices restituisce l'elenco dei valori ammessi.
+func (c *choiceValue) Choices() []string {
+	return c.choices
+}
+
+/*
+NewChoiceArg crea un argomento il cui valore deve appartenere all'insieme choices.
+
+Se il valore non è ammesso, l'errore restituito è del tipo "value 'foo' for -mode must be one of [a|b|c]".
+Describe mostra le scelte ammesse al posto del generico "=value", es. "-mode={a|b|c}".
+*/
+func NewChoiceArg(word string, id string, required bool, dest *string, choices []string, help string) *Argument {
+	if dest == nil || len(choices) == 0 {
+		return nil
+	}
+	label := strings.TrimSpace(word)
+	if len(label) == 0 {
+		label = id
+	}
+	cv := &choiceValue{dest: dest, choices: choices, label: label}
+	return NewArg(word, id, required, cv, help)
+}
+
+// =======================================================
+
+// sliceValue è il ValueParser usato da NewSliceArg, accumula i valori invece di sovrascriverli.
+type sliceValue struct {
+	strs *[]string
+	ints *[]int
+}
+
+// Parse aggiunge str alla lista di destinazione, convertendolo se la lista è di interi.
+func (s *sliceValue) Parse(str string) (err error) {
+	if s.strs != nil {
+		*s.strs = append(*s.strs, str)
+		return nil
+	}
+	var n int64
+	if n, err = strconv.ParseInt(str, 10, 0); err != nil {
+		return fmt.Errorf("not valid value '%s' for slice argument", str)
+	}
+	*s.ints = append(*s.ints, int(n))
+	return nil
+}
+
+// String restituisce gli elementi accumulati separati da virgola.
+func (s *sliceValue) String() string {
+	if s.strs != nil {
+		return strings.Join(*s.strs, ",")
+	}
+	items := make([]string, len(*s.ints))
+	for i, n := range *s.ints {
+		items[i] = strconv.Itoa(n)
+	}
+	return strings.Join(items, ",")
+}
+
+// Type restituisce "[]string" o "[]int" a seconda della destinazione.
+func (s *sliceValue) Type() string {
+	if s.strs != nil {
+		return "[]string"
+	}
+	return "[]int"
+}
+
+/*
+NewSliceArg crea un argomento generico o nominale che accumula i valori anziché sovrascriverli, utile per un argomento di un'opzione ripetibile (es. "-I dir1 -I dir2").
+
+dest deve essere un puntatore a []string oppure a []int, altrimenti la funzione restituisce nil.
+*/
+func NewSliceArg(word string, id string, required bool, dest interface{}, help string) *Argument {
+	var sv *sliceValue
+	switch d := dest.(type) {
+	case *[]string:
+		sv = &sliceValue{strs: d}
+	case *[]int:
+		sv = &sliceValue{ints: d}
+	default:
+		return nil
+	}
+	return NewArg(word, id, required, sv, help)
+}